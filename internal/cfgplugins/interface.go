@@ -17,7 +17,6 @@ package cfgplugins
 import (
 	"fmt"
 	"math"
-	"sync"
 	"testing"
 
 	"github.com/openconfig/featureprofiles/internal/components"
@@ -34,73 +33,38 @@ const (
 	targetOutputPowerTolerancedBm = 1
 	targetFrequencyMHz            = 193100000
 	targetFrequencyToleranceMHz   = 100000
+	// defaultOperationalMode is the operational mode InterfaceConfig falls
+	// back to when InterfaceInitialize was never called for dut, matching
+	// the old package-level opmode default.
+	defaultOperationalMode = 1
 )
 
-var (
-	opmode uint16
-	once   sync.Once
-)
-
-// Temporary code for assigning opmode 1 maintained until opmode is Initialized in all .go file
-func init() {
-	opmode = 1
-}
-
-// InterfaceInitialize assigns OpMode with value received through operationalMode flag.
-func InterfaceInitialize(t *testing.T, dut *ondatra.DUTDevice, initialOperationalMode uint16) uint16 {
-	once.Do(func() {
-		t.Helper()
-		if initialOperationalMode == 0 { // '0' signals to use vendor-specific default
-			switch dut.Vendor() {
-			case ondatra.CISCO:
-				opmode = 5003
-				t.Logf("cfgplugins.Initialize: Cisco DUT, setting opmode to default: %d", opmode)
-			case ondatra.ARISTA:
-				opmode = 1
-				t.Logf("cfgplugins.Initialize: Arista DUT, setting opmode to default: %d", opmode)
-			case ondatra.JUNIPER:
-				opmode = 1
-				t.Logf("cfgplugins.Initialize: Juniper DUT, setting opmode to default: %d", opmode)
-			case ondatra.NOKIA:
-				opmode = 1083
-				t.Logf("cfgplugins.Initialize: Nokia DUT, setting opmode to default: %d", opmode)
-			default:
-				opmode = 1
-				t.Logf("cfgplugins.Initialize: Using global default opmode: %d", opmode)
-			}
-		} else {
-			opmode = initialOperationalMode
-			t.Logf("cfgplugins.Initialize: Using provided initialOperationalMode: %d", opmode)
-		}
-		t.Logf("cfgplugins.Initialize: Initialization complete. Final opmode set to: %d", opmode)
-	})
-	return InterfaceGetOpMode()
-}
-
-// InterfaceGetOpMode returns the opmode value after the Initialize function has been called
-func InterfaceGetOpMode() uint16 {
-	return opmode
-}
-
-// InterfaceConfig configures the interface with the given port.
+// InterfaceConfig configures the interface with the given port. It is a thin
+// wrapper over ConfigureInterfaceProfile for the common single optical
+// channel, no-tributary case.
 func InterfaceConfig(t *testing.T, dut *ondatra.DUTDevice, dp *ondatra.Port) {
 	t.Helper()
-	d := &oc.Root{}
-	i := d.GetOrCreateInterface(dp.Name())
-	i.Enabled = ygot.Bool(true)
-	i.Type = oc.IETFInterfaces_InterfaceType_ethernetCsmacd
-	gnmi.Replace(t, dut, gnmi.OC().Interface(dp.Name()).Config(), i)
-	if deviations.ExplicitDcoConfig(dut) {
-		transceiverName := gnmi.Get(t, dut, gnmi.OC().Interface(dp.Name()).Transceiver().State())
-		gnmi.Replace(t, dut, gnmi.OC().Component(transceiverName).Config(), &oc.Component{
-			Name: ygot.String(transceiverName),
-			Transceiver: &oc.Component_Transceiver{
-				ModuleFunctionalType: oc.TransportTypes_TRANSCEIVER_MODULE_FUNCTIONAL_TYPE_TYPE_DIGITAL_COHERENT_OPTIC,
-			},
-		})
+	frequency := uint64(targetFrequencyMHz)
+	targetPower := float64(targetOutputPowerdBm)
+	opMode := uint16(defaultOperationalMode)
+	entry, ok := InterfaceGetOpModeEntryForDUT(dut)
+	if ok {
+		opMode = entry.OperationalMode
+		if entry.FrequencyGridMHz != 0 {
+			frequency = entry.FrequencyGridMHz
+		}
+		if entry.TargetOutputPowerMindBm != 0 || entry.TargetOutputPowerMaxdBm != 0 {
+			targetPower = (entry.TargetOutputPowerMindBm + entry.TargetOutputPowerMaxdBm) / 2
+		}
 	}
-	oc := components.OpticalChannelComponentFromPort(t, dut, dp)
-	ConfigOpticalChannel(t, dut, oc, targetFrequencyMHz, targetOutputPowerdBm, opmode)
+	profile := &InterfaceProfile{
+		OpticalChannels: []OpticalChannelSpec{{
+			Frequency:   frequency,
+			TargetPower: targetPower,
+			OpMode:      opMode,
+		}},
+	}
+	ConfigureInterfaceProfile(t, dut, dp, profile)
 }
 
 // ValidateInterfaceConfig validates the output power and frequency for the given port.
@@ -108,7 +72,14 @@ func ValidateInterfaceConfig(t *testing.T, dut *ondatra.DUTDevice, dp *ondatra.P
 	t.Helper()
 	ocComponent := components.OpticalChannelComponentFromPort(t, dut, dp)
 	t.Logf("Got opticalChannelComponent from port: %s", ocComponent)
+	validateOpticalChannelComponent(t, dut, ocComponent, targetOutputPowerdBm, targetFrequencyMHz, targetOutputPowerTolerancedBm, targetFrequencyToleranceMHz)
+}
 
+// validateOpticalChannelComponent validates the output power and frequency of
+// an already-resolved optical channel component. It backs both
+// ValidateInterfaceConfig and ValidateInterfaceProfile.
+func validateOpticalChannelComponent(t *testing.T, dut *ondatra.DUTDevice, ocComponent string, targetOutputPowerdBm float64, targetFrequencyMHz uint64, targetOutputPowerTolerancedBm float64, targetFrequencyToleranceMHz float64) {
+	t.Helper()
 	outputPower := gnmi.Get(t, dut, gnmi.OC().Component(ocComponent).OpticalChannel().TargetOutputPower().State())
 	if math.Abs(float64(outputPower)-float64(targetOutputPowerdBm)) > targetOutputPowerTolerancedBm {
 		t.Fatalf("Output power is not within expected tolerance, got: %v want: %v tolerance: %v", outputPower, targetOutputPowerdBm, targetOutputPowerTolerancedBm)
@@ -129,92 +100,42 @@ func ToggleInterface(t *testing.T, dut *ondatra.DUTDevice, intf string, isEnable
 	gnmi.Replace(t, dut, gnmi.OC().Interface(intf).Config(), i)
 }
 
-// ConfigOpticalChannel configures the optical channel.
+// ConfigOpticalChannel configures the optical channel. It is a thin wrapper
+// over opticalChannelComponent, the same builder ConfigureInterfaceProfile
+// batches per OpticalChannelSpec.
 func ConfigOpticalChannel(t *testing.T, dut *ondatra.DUTDevice, och string, frequency uint64, targetOpticalPower float64, operationalMode uint16) {
-	gnmi.Replace(t, dut, gnmi.OC().Component(och).Config(), &oc.Component{
-		Name: ygot.String(och),
-		OpticalChannel: &oc.Component_OpticalChannel{
-			OperationalMode:   ygot.Uint16(operationalMode),
-			Frequency:         ygot.Uint64(frequency),
-			TargetOutputPower: ygot.Float64(targetOpticalPower),
-		},
-	})
+	gnmi.Replace(t, dut, gnmi.OC().Component(och).Config(), opticalChannelComponent(och, OpticalChannelSpec{
+		Frequency:   frequency,
+		TargetPower: targetOpticalPower,
+		OpMode:      operationalMode,
+	}))
 }
 
-// ConfigOTNChannel configures the OTN channel.
+// ConfigOTNChannel configures the OTN channel. It is a thin wrapper over
+// configureOTNChannelForTributary for the common single 400GE tributary case.
 func ConfigOTNChannel(t *testing.T, dut *ondatra.DUTDevice, och string, otnIndex, ethIndex uint32) {
 	t.Helper()
 	t.Logf(" otnIndex:%v, ethIndex: %v", otnIndex, ethIndex)
-	if deviations.OTNChannelTribUnsupported(dut) {
-		gnmi.Replace(t, dut, gnmi.OC().TerminalDevice().Channel(otnIndex).Config(), &oc.TerminalDevice_Channel{
-			Description:        ygot.String("OTN Logical Channel"),
-			Index:              ygot.Uint32(otnIndex),
-			LogicalChannelType: oc.TransportTypes_LOGICAL_ELEMENT_PROTOCOL_TYPE_PROT_OTN,
-			Assignment: map[uint32]*oc.TerminalDevice_Channel_Assignment{
-				0: {
-					Index:          ygot.Uint32(1),
-					OpticalChannel: ygot.String(och),
-					Description:    ygot.String("OTN to Optical Channel"),
-					Allocation:     ygot.Float64(400),
-					AssignmentType: oc.Assignment_AssignmentType_OPTICAL_CHANNEL,
-				},
-			},
-		})
-	} else {
-		gnmi.Replace(t, dut, gnmi.OC().TerminalDevice().Channel(otnIndex).Config(), &oc.TerminalDevice_Channel{
-			Description:        ygot.String("OTN Logical Channel"),
-			Index:              ygot.Uint32(otnIndex),
-			LogicalChannelType: oc.TransportTypes_LOGICAL_ELEMENT_PROTOCOL_TYPE_PROT_OTN,
-			TribProtocol:       oc.TransportTypes_TRIBUTARY_PROTOCOL_TYPE_PROT_400GE,
-			AdminState:         oc.TerminalDevice_AdminStateType_ENABLED,
-			Assignment: map[uint32]*oc.TerminalDevice_Channel_Assignment{
-				0: {
-					Index:          ygot.Uint32(0),
-					OpticalChannel: ygot.String(och),
-					Description:    ygot.String("OTN to Optical Channel"),
-					Allocation:     ygot.Float64(400),
-					AssignmentType: oc.Assignment_AssignmentType_OPTICAL_CHANNEL,
-				},
-			},
-		})
-	}
+	configureOTNChannelForTributary(t, dut, och, TributarySpec{
+		OtnIndex:     otnIndex,
+		EthIndex:     ethIndex,
+		RateClass:    oc.TransportTypes_TRIBUTARY_RATE_CLASS_TYPE_TRIB_RATE_400G,
+		TribProtocol: oc.TransportTypes_TRIBUTARY_PROTOCOL_TYPE_PROT_400GE,
+		Allocation:   400,
+	})
 }
 
-// ConfigETHChannel configures the ETH channel.
+// ConfigETHChannel configures the ETH channel. It is a thin wrapper over
+// configureETHChannelForTributary for the common single 400GE tributary case.
 func ConfigETHChannel(t *testing.T, dut *ondatra.DUTDevice, interfaceName, transceiverName string, otnIndex, ethIndex uint32) {
 	t.Helper()
-	var ingress = &oc.TerminalDevice_Channel_Ingress{}
-	if !deviations.EthChannelIngressParametersUnsupported(dut) {
-		ingress = &oc.TerminalDevice_Channel_Ingress{
-			Interface:   ygot.String(interfaceName),
-			Transceiver: ygot.String(transceiverName),
-		}
-	}
-	var assignment = map[uint32]*oc.TerminalDevice_Channel_Assignment{
-		0: {
-			Index:          ygot.Uint32(0),
-			LogicalChannel: ygot.Uint32(otnIndex),
-			Description:    ygot.String("ETH to OTN"),
-			Allocation:     ygot.Float64(400),
-			AssignmentType: oc.Assignment_AssignmentType_LOGICAL_CHANNEL,
-		},
-	}
-	if deviations.EthChannelAssignmentCiscoNumbering(dut) {
-		assignment[0].Index = ygot.Uint32(1)
-	}
-	var channel = &oc.TerminalDevice_Channel{
-		Description:        ygot.String("ETH Logical Channel"),
-		Index:              ygot.Uint32(ethIndex),
-		LogicalChannelType: oc.TransportTypes_LOGICAL_ELEMENT_PROTOCOL_TYPE_PROT_ETHERNET,
-		TribProtocol:       oc.TransportTypes_TRIBUTARY_PROTOCOL_TYPE_PROT_400GE,
-		Ingress:            ingress,
-		Assignment:         assignment,
-		AdminState:         oc.TerminalDevice_AdminStateType_ENABLED,
-	}
-	if !deviations.ChannelRateClassParametersUnsupported(dut) {
-		channel.RateClass = oc.TransportTypes_TRIBUTARY_RATE_CLASS_TYPE_TRIB_RATE_400G
-	}
-	gnmi.Replace(t, dut, gnmi.OC().TerminalDevice().Channel(ethIndex).Config(), channel)
+	configureETHChannelForTributary(t, dut, interfaceName, transceiverName, TributarySpec{
+		OtnIndex:     otnIndex,
+		EthIndex:     ethIndex,
+		RateClass:    oc.TransportTypes_TRIBUTARY_RATE_CLASS_TYPE_TRIB_RATE_400G,
+		TribProtocol: oc.TransportTypes_TRIBUTARY_PROTOCOL_TYPE_PROT_400GE,
+		Allocation:   400,
+	})
 }
 
 // SetupAggregateAtomically sets up the aggregate interface atomically.
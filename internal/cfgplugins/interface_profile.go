@@ -0,0 +1,283 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cfgplugins
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/openconfig/featureprofiles/internal/components"
+	"github.com/openconfig/featureprofiles/internal/deviations"
+	"github.com/openconfig/ondatra"
+	"github.com/openconfig/ondatra/gnmi"
+	"github.com/openconfig/ondatra/gnmi/oc"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// OpticalChannelSpec describes the tuning parameters for a single optical
+// channel component, e.g. one sub-carrier of a multi-carrier DCO pluggable.
+type OpticalChannelSpec struct {
+	Frequency   uint64
+	TargetPower float64
+	OpMode      uint16
+	// LineRateGbps is this optical channel's line rate, e.g. 400 or 800. It
+	// is the target that the Allocation of every TributarySpec referencing
+	// this channel must sum to; leave it zero for channels with no
+	// tributaries.
+	LineRateGbps float64
+}
+
+// TributarySpec describes one OTN->ETH tributary assignment riding on top of
+// one of the optical channels in an InterfaceProfile.
+type TributarySpec struct {
+	OtnIndex uint32
+	EthIndex uint32
+	// RateClass and TribProtocol are the OTN rate class / tributary
+	// protocol to advertise for this tributary, e.g. TRIB_RATE_400G and
+	// PROT_400GE for a single 400GE client, or the 800G/200G/100G
+	// equivalents for other lane splits.
+	RateClass    oc.E_TransportTypes_TRIBUTARY_RATE_CLASS_TYPE
+	TribProtocol oc.E_TransportTypes_TRIBUTARY_PROTOCOL_TYPE
+	// OpticalChannelRef is the index into InterfaceProfile.OpticalChannels
+	// that this tributary is assigned to.
+	OpticalChannelRef int
+	// Allocation is the bandwidth, in Gbps, this tributary claims out of
+	// its optical channel. The allocations of every tributary sharing an
+	// optical channel must sum to that channel's line rate.
+	Allocation float64
+}
+
+// InterfaceProfile describes the full set of optical channels and OTN/ETH
+// tributaries to provision on a single port, e.g. for an 800G-ZR+ pluggable
+// carrying multiple sub-carriers and/or multiple client lanes.
+type InterfaceProfile struct {
+	OpticalChannels []OpticalChannelSpec
+	Tributaries     []TributarySpec
+}
+
+// ConfigureInterfaceProfile configures the interface, one optical channel per
+// entry in profile.OpticalChannels, and the OTN/ETH tributary assignments in
+// profile.Tributaries. It returns the optical channel component names in the
+// same order as profile.OpticalChannels. InterfaceConfig is a thin wrapper
+// over this function for the common single-channel, no-tributary case.
+func ConfigureInterfaceProfile(t *testing.T, dut *ondatra.DUTDevice, dp *ondatra.Port, profile *InterfaceProfile) []string {
+	t.Helper()
+	d := &oc.Root{}
+	i := d.GetOrCreateInterface(dp.Name())
+	i.Enabled = ygot.Bool(true)
+	i.Type = oc.IETFInterfaces_InterfaceType_ethernetCsmacd
+	gnmi.Replace(t, dut, gnmi.OC().Interface(dp.Name()).Config(), i)
+	if deviations.ExplicitDcoConfig(dut) {
+		transceiverName := gnmi.Get(t, dut, gnmi.OC().Interface(dp.Name()).Transceiver().State())
+		gnmi.Replace(t, dut, gnmi.OC().Component(transceiverName).Config(), &oc.Component{
+			Name: ygot.String(transceiverName),
+			Transceiver: &oc.Component_Transceiver{
+				ModuleFunctionalType: oc.TransportTypes_TRANSCEIVER_MODULE_FUNCTIONAL_TYPE_TYPE_DIGITAL_COHERENT_OPTIC,
+			},
+		})
+	}
+
+	primaryOch := components.OpticalChannelComponentFromPort(t, dut, dp)
+	ochComponents, err := deriveSiblingOpticalChannelNames(primaryOch, len(profile.OpticalChannels))
+	if err != nil {
+		t.Fatalf("cfgplugins.ConfigureInterfaceProfile: %v", err)
+	}
+
+	if err := validateTributaryRefs(profile.Tributaries, len(ochComponents)); err != nil {
+		t.Fatalf("cfgplugins.ConfigureInterfaceProfile: %v", err)
+	}
+	if err := validateTributaryAllocations(profile.Tributaries, profile.OpticalChannels); err != nil {
+		t.Fatalf("cfgplugins.ConfigureInterfaceProfile: %v", err)
+	}
+
+	batch := &gnmi.SetBatch{}
+	for idx, spec := range profile.OpticalChannels {
+		och := ochComponents[idx]
+		gnmi.BatchReplace(batch, gnmi.OC().Component(och).Config(), opticalChannelComponent(och, spec))
+	}
+	batch.Set(t, dut)
+
+	var transceiverName string
+	if len(profile.Tributaries) > 0 && !deviations.EthChannelIngressParametersUnsupported(dut) {
+		transceiverName = gnmi.Get(t, dut, gnmi.OC().Interface(dp.Name()).Transceiver().State())
+	}
+	for _, trib := range profile.Tributaries {
+		configureOTNChannelForTributary(t, dut, ochComponents[trib.OpticalChannelRef], trib)
+		configureETHChannelForTributary(t, dut, dp.Name(), transceiverName, trib)
+	}
+
+	return ochComponents
+}
+
+// opticalChannelComponent builds the OpticalChannel component config for a
+// single OpticalChannelSpec, shared by ConfigureInterfaceProfile's batch and
+// the single-channel ConfigOpticalChannel wrapper.
+func opticalChannelComponent(och string, spec OpticalChannelSpec) *oc.Component {
+	return &oc.Component{
+		Name: ygot.String(och),
+		OpticalChannel: &oc.Component_OpticalChannel{
+			OperationalMode:   ygot.Uint16(spec.OpMode),
+			Frequency:         ygot.Uint64(spec.Frequency),
+			TargetOutputPower: ygot.Float64(spec.TargetPower),
+		},
+	}
+}
+
+// trailingIndexRE matches the numeric suffix of an optical channel component
+// name, e.g. the "5" in "0/0/0/5".
+var trailingIndexRE = regexp.MustCompile(`\d+$`)
+
+// deriveSiblingOpticalChannelNames returns n optical channel component names:
+// first verbatim, then its sibling subports obtained by incrementing the
+// trailing numeric index of first. This covers multi-carrier DCO pluggables,
+// whose additional optical channels are enumerated components adjacent to
+// the primary one returned by components.OpticalChannelComponentFromPort.
+func deriveSiblingOpticalChannelNames(first string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("n must be positive, got %d", n)
+	}
+	names := make([]string, n)
+	names[0] = first
+	if n == 1 {
+		return names, nil
+	}
+	loc := trailingIndexRE.FindStringIndex(first)
+	if loc == nil {
+		return nil, fmt.Errorf("optical channel component %q has no trailing index to derive %d sibling subports from", first, n-1)
+	}
+	prefix := first[:loc[0]]
+	base, err := strconv.Atoi(first[loc[0]:loc[1]])
+	if err != nil {
+		return nil, fmt.Errorf("optical channel component %q: %w", first, err)
+	}
+	for i := 1; i < n; i++ {
+		names[i] = fmt.Sprintf("%s%d", prefix, base+i)
+	}
+	return names, nil
+}
+
+// validateTributaryRefs checks that every TributarySpec.OpticalChannelRef
+// points at one of numOpticalChannels optical channels, so a malformed
+// InterfaceProfile fails fast with a clear error instead of panicking on an
+// out-of-range slice index.
+func validateTributaryRefs(tributaries []TributarySpec, numOpticalChannels int) error {
+	for i, trib := range tributaries {
+		if trib.OpticalChannelRef < 0 || trib.OpticalChannelRef >= numOpticalChannels {
+			return fmt.Errorf("tributary %d: OpticalChannelRef %d out of range [0,%d)", i, trib.OpticalChannelRef, numOpticalChannels)
+		}
+	}
+	return nil
+}
+
+// allocationSumToleranceGbps absorbs floating-point rounding when comparing
+// summed tributary allocations against an optical channel's line rate.
+const allocationSumToleranceGbps = 1e-6
+
+// validateTributaryAllocations checks that, for every optical channel that at
+// least one tributary references via OpticalChannelRef, the Allocation of those
+// tributaries sums to that channel's LineRateGbps. Optical channels with no
+// tributaries are not checked. Callers must call validateTributaryRefs first
+// so every OpticalChannelRef is known to be in range.
+func validateTributaryAllocations(tributaries []TributarySpec, opticalChannels []OpticalChannelSpec) error {
+	sums := make(map[int]float64)
+	for _, trib := range tributaries {
+		sums[trib.OpticalChannelRef] += trib.Allocation
+	}
+	for ref, sum := range sums {
+		want := opticalChannels[ref].LineRateGbps
+		if diff := sum - want; diff > allocationSumToleranceGbps || diff < -allocationSumToleranceGbps {
+			return fmt.Errorf("optical channel %d: tributary allocations sum to %vGbps, want %vGbps (its LineRateGbps)", ref, sum, want)
+		}
+	}
+	return nil
+}
+
+// configureOTNChannelForTributary writes the OTN logical channel and its
+// assignment to the optical channel for a single tributary of a profile.
+func configureOTNChannelForTributary(t *testing.T, dut *ondatra.DUTDevice, och string, trib TributarySpec) {
+	t.Helper()
+	channel := &oc.TerminalDevice_Channel{
+		Description:        ygot.String("OTN Logical Channel"),
+		Index:              ygot.Uint32(trib.OtnIndex),
+		LogicalChannelType: oc.TransportTypes_LOGICAL_ELEMENT_PROTOCOL_TYPE_PROT_OTN,
+		Assignment: map[uint32]*oc.TerminalDevice_Channel_Assignment{
+			0: {
+				Index:          ygot.Uint32(0),
+				OpticalChannel: ygot.String(och),
+				Description:    ygot.String("OTN to Optical Channel"),
+				Allocation:     ygot.Float64(trib.Allocation),
+				AssignmentType: oc.Assignment_AssignmentType_OPTICAL_CHANNEL,
+			},
+		},
+	}
+	if deviations.OTNChannelTribUnsupported(dut) {
+		channel.Assignment[0].Index = ygot.Uint32(1)
+	} else {
+		channel.TribProtocol = trib.TribProtocol
+		channel.AdminState = oc.TerminalDevice_AdminStateType_ENABLED
+	}
+	gnmi.Replace(t, dut, gnmi.OC().TerminalDevice().Channel(trib.OtnIndex).Config(), channel)
+}
+
+// configureETHChannelForTributary writes the ETH logical channel and its
+// assignment to the OTN channel for a single tributary of a profile. It backs
+// both ConfigureInterfaceProfile and the single-tributary ConfigETHChannel
+// wrapper.
+func configureETHChannelForTributary(t *testing.T, dut *ondatra.DUTDevice, interfaceName, transceiverName string, trib TributarySpec) {
+	t.Helper()
+	var ingress = &oc.TerminalDevice_Channel_Ingress{}
+	if !deviations.EthChannelIngressParametersUnsupported(dut) {
+		ingress = &oc.TerminalDevice_Channel_Ingress{
+			Interface:   ygot.String(interfaceName),
+			Transceiver: ygot.String(transceiverName),
+		}
+	}
+	assignment := map[uint32]*oc.TerminalDevice_Channel_Assignment{
+		0: {
+			Index:          ygot.Uint32(0),
+			LogicalChannel: ygot.Uint32(trib.OtnIndex),
+			Description:    ygot.String("ETH to OTN"),
+			Allocation:     ygot.Float64(trib.Allocation),
+			AssignmentType: oc.Assignment_AssignmentType_LOGICAL_CHANNEL,
+		},
+	}
+	if deviations.EthChannelAssignmentCiscoNumbering(dut) {
+		assignment[0].Index = ygot.Uint32(1)
+	}
+	channel := &oc.TerminalDevice_Channel{
+		Description:        ygot.String("ETH Logical Channel"),
+		Index:              ygot.Uint32(trib.EthIndex),
+		LogicalChannelType: oc.TransportTypes_LOGICAL_ELEMENT_PROTOCOL_TYPE_PROT_ETHERNET,
+		TribProtocol:       trib.TribProtocol,
+		Ingress:            ingress,
+		Assignment:         assignment,
+		AdminState:         oc.TerminalDevice_AdminStateType_ENABLED,
+	}
+	if !deviations.ChannelRateClassParametersUnsupported(dut) {
+		channel.RateClass = trib.RateClass
+	}
+	gnmi.Replace(t, dut, gnmi.OC().TerminalDevice().Channel(trib.EthIndex).Config(), channel)
+}
+
+// ValidateInterfaceProfile validates the output power and frequency of every
+// optical channel in profile against the values read back from the DUT.
+func ValidateInterfaceProfile(t *testing.T, dut *ondatra.DUTDevice, ochComponents []string, profile *InterfaceProfile, outputPowerTolerancedBm float64, frequencyToleranceMHz float64) {
+	t.Helper()
+	for idx, spec := range profile.OpticalChannels {
+		validateOpticalChannelComponent(t, dut, ochComponents[idx], spec.TargetPower, spec.Frequency, outputPowerTolerancedBm, frequencyToleranceMHz)
+	}
+}
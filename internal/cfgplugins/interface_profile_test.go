@@ -0,0 +1,176 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cfgplugins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeriveSiblingOpticalChannelNames(t *testing.T) {
+	tests := []struct {
+		desc    string
+		first   string
+		n       int
+		want    []string
+		wantErr bool
+	}{
+		{
+			desc:  "single channel returns first verbatim",
+			first: "0/0/0/5",
+			n:     1,
+			want:  []string{"0/0/0/5"},
+		},
+		{
+			desc:  "two siblings increment trailing index",
+			first: "0/0/0/5",
+			n:     2,
+			want:  []string{"0/0/0/5", "0/0/0/6"},
+		},
+		{
+			desc:  "four siblings",
+			first: "1/0/1/1",
+			n:     4,
+			want:  []string{"1/0/1/1", "1/0/1/2", "1/0/1/3", "1/0/1/4"},
+		},
+		{
+			desc:    "no trailing index cannot derive siblings",
+			first:   "Optical",
+			n:       2,
+			wantErr: true,
+		},
+		{
+			desc:    "n must be positive",
+			first:   "0/0/0/5",
+			n:       0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := deriveSiblingOpticalChannelNames(tt.first, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deriveSiblingOpticalChannelNames(%q, %d) err = %v, wantErr %v", tt.first, tt.n, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deriveSiblingOpticalChannelNames(%q, %d) = %v, want %v", tt.first, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTributaryRefs(t *testing.T) {
+	tests := []struct {
+		desc        string
+		tributaries []TributarySpec
+		numOch      int
+		wantErr     bool
+	}{
+		{
+			desc:        "no tributaries is always valid",
+			tributaries: nil,
+			numOch:      1,
+		},
+		{
+			desc:        "in-range refs",
+			tributaries: []TributarySpec{{OpticalChannelRef: 0}, {OpticalChannelRef: 1}},
+			numOch:      2,
+		},
+		{
+			desc:        "ref equal to count is out of range",
+			tributaries: []TributarySpec{{OpticalChannelRef: 2}},
+			numOch:      2,
+			wantErr:     true,
+		},
+		{
+			desc:        "negative ref is out of range",
+			tributaries: []TributarySpec{{OpticalChannelRef: -1}},
+			numOch:      2,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := validateTributaryRefs(tt.tributaries, tt.numOch)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTributaryRefs(%v, %d) err = %v, wantErr %v", tt.tributaries, tt.numOch, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTributaryAllocations(t *testing.T) {
+	tests := []struct {
+		desc        string
+		tributaries []TributarySpec
+		och         []OpticalChannelSpec
+		wantErr     bool
+	}{
+		{
+			desc: "no tributaries is always valid",
+			och:  []OpticalChannelSpec{{LineRateGbps: 400}},
+		},
+		{
+			desc:        "single tributary matches line rate",
+			tributaries: []TributarySpec{{OpticalChannelRef: 0, Allocation: 400}},
+			och:         []OpticalChannelSpec{{LineRateGbps: 400}},
+		},
+		{
+			desc: "two tributaries sum to line rate",
+			tributaries: []TributarySpec{
+				{OpticalChannelRef: 0, Allocation: 200},
+				{OpticalChannelRef: 0, Allocation: 200},
+			},
+			och: []OpticalChannelSpec{{LineRateGbps: 400}},
+		},
+		{
+			desc: "under-allocation is rejected",
+			tributaries: []TributarySpec{
+				{OpticalChannelRef: 0, Allocation: 100},
+			},
+			och:     []OpticalChannelSpec{{LineRateGbps: 400}},
+			wantErr: true,
+		},
+		{
+			desc: "over-allocation is rejected",
+			tributaries: []TributarySpec{
+				{OpticalChannelRef: 0, Allocation: 200},
+				{OpticalChannelRef: 0, Allocation: 300},
+			},
+			och:     []OpticalChannelSpec{{LineRateGbps: 400}},
+			wantErr: true,
+		},
+		{
+			desc: "each optical channel is checked independently",
+			tributaries: []TributarySpec{
+				{OpticalChannelRef: 0, Allocation: 400},
+				{OpticalChannelRef: 1, Allocation: 100},
+			},
+			och:     []OpticalChannelSpec{{LineRateGbps: 400}, {LineRateGbps: 400}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := validateTributaryAllocations(tt.tributaries, tt.och)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTributaryAllocations(%v, %v) err = %v, wantErr %v", tt.tributaries, tt.och, err, tt.wantErr)
+			}
+		})
+	}
+}
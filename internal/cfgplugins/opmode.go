@@ -0,0 +1,233 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cfgplugins
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/openconfig/ondatra"
+)
+
+//go:embed opmodes/*.json
+var opModeFS embed.FS
+
+// OpModeKey identifies a single row of the operational-mode registry: a
+// vendor's operational mode for a given transceiver form factor, line rate
+// and modulation.
+type OpModeKey struct {
+	Vendor     ondatra.Vendor
+	FormFactor string
+	LineRate   string
+	Modulation string
+}
+
+// OpModeEntry is the operational mode and its expected tuning envelope for
+// one OpModeKey.
+type OpModeEntry struct {
+	OperationalMode         uint16
+	FrequencyGridMHz        uint64
+	TargetOutputPowerMindBm float64
+	TargetOutputPowerMaxdBm float64
+}
+
+// OpModeRegistry resolves (vendor, form factor, line rate, modulation)
+// tuples to the operational mode and tuning envelope a vendor expects,
+// replacing the old package-level, single-vendor opmode default.
+type OpModeRegistry struct {
+	entries map[OpModeKey]OpModeEntry
+}
+
+// opModeFileEntry mirrors one row of an opmodes/*.json registry file.
+type opModeFileEntry struct {
+	Vendor                  string  `json:"vendor"`
+	FormFactor              string  `json:"form_factor"`
+	LineRate                string  `json:"line_rate"`
+	Modulation              string  `json:"modulation"`
+	OperationalMode         uint16  `json:"operational_mode"`
+	FrequencyGridMHz        uint64  `json:"frequency_grid_mhz"`
+	TargetOutputPowerMindBm float64 `json:"target_output_power_min_dbm"`
+	TargetOutputPowerMaxdBm float64 `json:"target_output_power_max_dbm"`
+}
+
+// NewOpModeRegistry builds an OpModeRegistry from every opmodes/*.json file
+// embedded alongside this package. Vendors extend the registry by adding a
+// row to one of those files; no Go changes are required.
+func NewOpModeRegistry() (*OpModeRegistry, error) {
+	files, err := opModeFS.ReadDir("opmodes")
+	if err != nil {
+		return nil, fmt.Errorf("cfgplugins: reading opmodes directory: %w", err)
+	}
+	reg := &OpModeRegistry{entries: map[OpModeKey]OpModeEntry{}}
+	for _, f := range files {
+		b, err := opModeFS.ReadFile("opmodes/" + f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("cfgplugins: reading %s: %w", f.Name(), err)
+		}
+		var rows []opModeFileEntry
+		if err := json.Unmarshal(b, &rows); err != nil {
+			return nil, fmt.Errorf("cfgplugins: parsing %s: %w", f.Name(), err)
+		}
+		for _, row := range rows {
+			vendor, err := vendorFromString(row.Vendor)
+			if err != nil {
+				return nil, fmt.Errorf("cfgplugins: parsing %s: %w", f.Name(), err)
+			}
+			key := OpModeKey{
+				Vendor:     vendor,
+				FormFactor: row.FormFactor,
+				LineRate:   row.LineRate,
+				Modulation: row.Modulation,
+			}
+			reg.entries[key] = OpModeEntry{
+				OperationalMode:         row.OperationalMode,
+				FrequencyGridMHz:        row.FrequencyGridMHz,
+				TargetOutputPowerMindBm: row.TargetOutputPowerMindBm,
+				TargetOutputPowerMaxdBm: row.TargetOutputPowerMaxdBm,
+			}
+		}
+	}
+	return reg, nil
+}
+
+// vendorByName maps the vendor strings used in opmodes/*.json files to the
+// ondatra.Vendor enum. ondatra.Vendor is int-backed, so it cannot be
+// produced from a JSON string by a plain conversion.
+var vendorByName = map[string]ondatra.Vendor{
+	"CISCO":   ondatra.CISCO,
+	"ARISTA":  ondatra.ARISTA,
+	"JUNIPER": ondatra.JUNIPER,
+	"NOKIA":   ondatra.NOKIA,
+}
+
+// vendorFromString looks up the ondatra.Vendor for a vendor string as used in
+// opmodes/*.json, returning an error for anything unrecognized.
+func vendorFromString(s string) (ondatra.Vendor, error) {
+	v, ok := vendorByName[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown vendor %q", s)
+	}
+	return v, nil
+}
+
+// Lookup returns the OpModeEntry registered for key, if any.
+func (r *OpModeRegistry) Lookup(key OpModeKey) (OpModeEntry, bool) {
+	entry, ok := r.entries[key]
+	return entry, ok
+}
+
+var (
+	opModeRegistryOnce sync.Once
+	opModeRegistry     *OpModeRegistry
+	opModeRegistryErr  error
+)
+
+// defaultOpModeRegistry lazily loads and caches the registry built from the
+// embedded opmodes/*.json files.
+func defaultOpModeRegistry() (*OpModeRegistry, error) {
+	opModeRegistryOnce.Do(func() {
+		opModeRegistry, opModeRegistryErr = NewOpModeRegistry()
+	})
+	return opModeRegistry, opModeRegistryErr
+}
+
+// OpModeSelector picks the row of the OpModeRegistry to resolve for a DUT.
+// Set OperationalMode directly to bypass the registry, e.g. in tests that
+// need to force a specific, possibly invalid, mode.
+type OpModeSelector struct {
+	FormFactor      string
+	LineRate        string
+	Modulation      string
+	OperationalMode uint16
+}
+
+var (
+	opModeMu           sync.Mutex
+	opModeEntryByDUT   = map[string]OpModeEntry{}
+	defaultDUTName     string
+	defaultDUTNameOnce sync.Once
+)
+
+// InterfaceInitialize resolves and records the operational mode, frequency
+// grid and output power range for dut according to selector, so that
+// multiple DUTs of different vendors in the same test run each get their own
+// values instead of racing on a single package-level variable. It returns
+// the resolved operational mode.
+func InterfaceInitialize(t *testing.T, dut *ondatra.DUTDevice, selector OpModeSelector) uint16 {
+	t.Helper()
+	defaultDUTNameOnce.Do(func() { defaultDUTName = dut.Name() })
+
+	var entry OpModeEntry
+	if selector.OperationalMode != 0 {
+		entry = OpModeEntry{OperationalMode: selector.OperationalMode}
+		t.Logf("cfgplugins.InterfaceInitialize: %s using provided operational mode: %d", dut.Name(), entry.OperationalMode)
+	} else {
+		reg, err := defaultOpModeRegistry()
+		if err != nil {
+			t.Fatalf("cfgplugins.InterfaceInitialize: could not load opmode registry: %v", err)
+		}
+		key := OpModeKey{
+			Vendor:     dut.Vendor(),
+			FormFactor: selector.FormFactor,
+			LineRate:   selector.LineRate,
+			Modulation: selector.Modulation,
+		}
+		ok := false
+		entry, ok = reg.Lookup(key)
+		if !ok {
+			t.Fatalf("cfgplugins.InterfaceInitialize: no opmode registered for %+v", key)
+		}
+		t.Logf("cfgplugins.InterfaceInitialize: %s resolved opmode %d from registry for %+v", dut.Name(), entry.OperationalMode, key)
+	}
+
+	opModeMu.Lock()
+	opModeEntryByDUT[dut.Name()] = entry
+	opModeMu.Unlock()
+	return entry.OperationalMode
+}
+
+// InterfaceGetOpModeEntryForDUT returns the full OpModeEntry — operational
+// mode, frequency grid and output power range — resolved for dut by a prior
+// call to InterfaceInitialize. ok is false if dut was never initialized. If
+// dut was initialized with an explicit OpModeSelector.OperationalMode override
+// that bypassed the registry, ok is true and only OperationalMode is set; the
+// frequency grid and output power range are zero.
+func InterfaceGetOpModeEntryForDUT(dut *ondatra.DUTDevice) (entry OpModeEntry, ok bool) {
+	opModeMu.Lock()
+	defer opModeMu.Unlock()
+	entry, ok = opModeEntryByDUT[dut.Name()]
+	return entry, ok
+}
+
+// InterfaceGetOpModeForDUT returns the operational mode resolved for dut by
+// a prior call to InterfaceInitialize.
+func InterfaceGetOpModeForDUT(dut *ondatra.DUTDevice) uint16 {
+	entry, _ := InterfaceGetOpModeEntryForDUT(dut)
+	return entry.OperationalMode
+}
+
+// InterfaceGetOpMode returns the operational mode resolved for the first DUT
+// passed to InterfaceInitialize.
+//
+// Deprecated: use InterfaceGetOpModeForDUT so multi-DUT runs each get their
+// own mode instead of sharing the first DUT initialized.
+func InterfaceGetOpMode() uint16 {
+	opModeMu.Lock()
+	defer opModeMu.Unlock()
+	return opModeEntryByDUT[defaultDUTName].OperationalMode
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cfgplugins
+
+import (
+	"testing"
+
+	"github.com/openconfig/ondatra"
+)
+
+func TestVendorFromString(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ondatra.Vendor
+		wantErr bool
+	}{
+		{in: "CISCO", want: ondatra.CISCO},
+		{in: "ARISTA", want: ondatra.ARISTA},
+		{in: "JUNIPER", want: ondatra.JUNIPER},
+		{in: "NOKIA", want: ondatra.NOKIA},
+		{in: "NOT_A_VENDOR", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := vendorFromString(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("vendorFromString(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("vendorFromString(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpModeRegistryLookup(t *testing.T) {
+	reg, err := NewOpModeRegistry()
+	if err != nil {
+		t.Fatalf("NewOpModeRegistry() failed: %v", err)
+	}
+
+	tests := []struct {
+		desc     string
+		key      OpModeKey
+		wantMode uint16
+		wantOK   bool
+	}{
+		{
+			desc:     "cisco 400G 8QAM resolves the vendor default",
+			key:      OpModeKey{Vendor: ondatra.CISCO, FormFactor: "QSFP-DD", LineRate: "400G", Modulation: "8QAM"},
+			wantMode: 5003,
+			wantOK:   true,
+		},
+		{
+			desc:     "nokia 400G 16QAM resolves the vendor default",
+			key:      OpModeKey{Vendor: ondatra.NOKIA, FormFactor: "QSFP-DD", LineRate: "400G", Modulation: "16QAM"},
+			wantMode: 1083,
+			wantOK:   true,
+		},
+		{
+			desc:   "unknown line rate is not registered",
+			key:    OpModeKey{Vendor: ondatra.CISCO, FormFactor: "QSFP-DD", LineRate: "800G", Modulation: "8QAM"},
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			entry, ok := reg.Lookup(tt.key)
+			if ok != tt.wantOK {
+				t.Fatalf("Lookup(%+v) ok = %v, want %v", tt.key, ok, tt.wantOK)
+			}
+			if ok && entry.OperationalMode != tt.wantMode {
+				t.Errorf("Lookup(%+v).OperationalMode = %d, want %d", tt.key, entry.OperationalMode, tt.wantMode)
+			}
+		})
+	}
+}